@@ -34,7 +34,6 @@ func writeFile() error {
 
 	// write encrypted compressed json-data
 	w := gzip.NewWriter(enc.Seal(file))
-	defer w.Close()
 
 	for _, datum := range []string{"foo", "bar", "baz"} {
 		if err = json.NewEncoder(w).Encode(datum); err != nil {
@@ -42,7 +41,12 @@ func writeFile() error {
 		}
 	}
 
-	return nil
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	// mark a clean end of stream so the reader can detect truncation
+	return enc.Finish()
 }
 
 func readFile() error {
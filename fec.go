@@ -0,0 +1,192 @@
+package streamcrypt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// fec.go implements WithReedSolomon: instead of writing a fixed-size chunk's
+// ciphertext as a single blob, it's padded to a multiple of rsData and split
+// into rsData+rsParity equal shares, each prefixed with a tiny header, so
+// Open can reconstruct a chunk even if some of its shares are missing or
+// corrupted. This only makes sense alongside the fixed-chunk framing from
+// WithChunkSize, since the padding needs a known chunk size to be
+// deterministic across the whole stream.
+
+// shareHeaderSize is the fixed on-disk size of the header written before
+// every share: share index(1) + chunk index(8) + real ciphertext length(4)
+// + CRC32 of the share's data(4), letting Open detect a corrupted share
+// without waiting for the whole stripe's AEAD tag to fail.
+const shareHeaderSize = 1 + 8 + 4 + 4
+
+// FECStats reports how many chunks Open recovered via Reed-Solomon
+// reconstruction, versus how many it couldn't recover at all. It's only
+// meaningful for streams sealed with WithReedSolomon.
+type FECStats struct {
+	Recovered     int
+	Unrecoverable int
+}
+
+// WithReedSolomon wraps each fixed-size chunk's ciphertext in a stripe of
+// dataShards+parityShards equal shares instead of writing it as a single
+// blob, so single-bit flips or small contiguous corruption in a share can be
+// repaired on Open without needing the rest of the stream. Like
+// WithParallelism, it implies fixed chunking: if WithChunkSize hasn't been
+// called, chunkSize defaults to defaultChunkSize. It isn't meant to be
+// combined with WithParallelism; if both are set, FEC framing takes
+// priority and parallelism is ignored.
+func (e *Encryptor) WithReedSolomon(dataShards, parityShards int) *Encryptor {
+	e.rsData = dataShards
+	e.rsParity = parityShards
+	if e.chunkSize == 0 {
+		e.chunkSize = defaultChunkSize
+	}
+	return e
+}
+
+// Stats returns the Reed-Solomon recovery counters accumulated so far by
+// Open. It's only meaningful for streams sealed with WithReedSolomon.
+func (e *Encryptor) Stats() FECStats {
+	return e.rsStats
+}
+
+// rsShareSizeFor returns the per-share size that lets a ciphertext of up to
+// chunkSize+overhead bytes split evenly across dataShards shares, padding
+// the last share with zeros when it doesn't divide evenly.
+func rsShareSizeFor(chunkSize, overhead, dataShards int) int {
+	total := chunkSize + overhead
+	return (total + dataShards - 1) / dataShards
+}
+
+// writeFixedChunkFEC seals p like writeFixedChunk, then pads the ciphertext
+// to rsShareSize*rsData bytes, splits it into rsData data shares, computes
+// rsParity parity shares, and writes the whole stripe downstream, one
+// header-prefixed share at a time.
+func (e *Encryptor) writeFixedChunkFEC(p []byte) error {
+	padded := make([]byte, e.rsShareSize*e.rsData)
+	sealed := e.enc.Seal(padded[:0], nil, p, chunkAAD(e.writeAAD[:], e.writeFileID, e.writeChunkIndex))
+	realLen := uint32(len(sealed))
+
+	shards := make([][]byte, e.rsData+e.rsParity)
+	for i := 0; i < e.rsData; i++ {
+		shards[i] = padded[i*e.rsShareSize : (i+1)*e.rsShareSize]
+	}
+	for i := e.rsData; i < len(shards); i++ {
+		shards[i] = make([]byte, e.rsShareSize)
+	}
+	if err := e.rsEncoder.Encode(shards); err != nil {
+		return err
+	}
+
+	header := make([]byte, shareHeaderSize)
+	binary.BigEndian.PutUint64(header[1:9], e.writeChunkIndex)
+	binary.BigEndian.PutUint32(header[9:13], realLen)
+	for i, shard := range shards {
+		header[0] = byte(i)
+		binary.BigEndian.PutUint32(header[13:shareHeaderSize], crc32.ChecksumIEEE(shard))
+		if _, err := e.downstream.Write(header); err != nil {
+			return err
+		}
+		if _, err := e.downstream.Write(shard); err != nil {
+			return err
+		}
+	}
+
+	e.writeChunkIndex++
+	return nil
+}
+
+// readFixedChunkFEC reads a full stripe of rsData+rsParity shares, treating
+// any share that's short or fails its CRC32 check as missing (any other read
+// error is returned as is), reconstructs the stripe with Reed-Solomon if any
+// shares are missing, and then authenticates and decrypts the reassembled
+// chunk against the expected chunk index exactly like nextChunk does. It mirrors nextParallelChunk's sentinel/truncation
+// handling: a zero-length decoded chunk is Finish's sentinel, and an
+// upstream that ends before the sentinel is seen is ErrTruncatedStream.
+func (e *Encryptor) readFixedChunkFEC() ([]byte, error) {
+	n := e.rsData + e.rsParity
+	shards := make([][]byte, n)
+
+	var chunkIndex uint64
+	var realLen uint32
+	gotHeader := false
+	sawAnyByte := false
+
+	for i := 0; i < n; i++ {
+		buf := make([]byte, shareHeaderSize+e.rsShareSize)
+		read, rerr := io.ReadFull(e.upstream, buf)
+		switch rerr {
+		case nil:
+			sawAnyByte = true
+		case io.ErrUnexpectedEOF:
+			sawAnyByte = sawAnyByte || read > 0
+			continue
+		case io.EOF:
+			continue
+		default:
+			return nil, rerr
+		}
+
+		shareIndex := buf[0]
+		idx := binary.BigEndian.Uint64(buf[1:9])
+		length := binary.BigEndian.Uint32(buf[9:13])
+		sum := binary.BigEndian.Uint32(buf[13:shareHeaderSize])
+		data := buf[shareHeaderSize:]
+
+		if int(shareIndex) != i || crc32.ChecksumIEEE(data) != sum {
+			continue // corrupted share: leave shards[i] nil, let RS reconstruct it
+		}
+
+		if !gotHeader {
+			chunkIndex, realLen, gotHeader = idx, length, true
+		}
+		shards[i] = data
+	}
+
+	if !sawAnyByte {
+		if !e.rsSawSentinel {
+			return nil, ErrTruncatedStream
+		}
+		return nil, io.EOF
+	}
+	if !gotHeader {
+		return nil, fmt.Errorf("streamcrypt: every share in chunk's stripe was missing or corrupted")
+	}
+	if chunkIndex != e.readChunkIndex {
+		return nil, fmt.Errorf("streamcrypt: unexpected chunk index %d, want %d", chunkIndex, e.readChunkIndex)
+	}
+
+	missing := 0
+	for _, s := range shards {
+		if s == nil {
+			missing++
+		}
+	}
+	if missing > 0 {
+		if err := e.rsEncoder.Reconstruct(shards); err != nil {
+			e.rsStats.Unrecoverable++
+			return nil, fmt.Errorf("streamcrypt: reed-solomon reconstruction failed: %w", err)
+		}
+		e.rsStats.Recovered++
+	}
+
+	ciphertext := make([]byte, 0, realLen)
+	for _, s := range shards[:e.rsData] {
+		ciphertext = append(ciphertext, s...)
+	}
+	ciphertext = ciphertext[:realLen]
+
+	plaintext, err := e.enc.Open(ciphertext[:0], nil, ciphertext, chunkAAD(e.readAAD[:], e.readFileID, chunkIndex))
+	if err != nil {
+		return nil, err
+	}
+	e.readChunkIndex = chunkIndex + 1
+
+	if len(plaintext) == 0 {
+		e.rsSawSentinel = true
+		return nil, io.EOF
+	}
+	return plaintext, nil
+}
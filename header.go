@@ -0,0 +1,121 @@
+package streamcrypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magic identifies a streamcrypt stream and its framing version.
+var magic = [8]byte{'S', 'C', 'R', 'Y', 'P', 'T', '1', 0}
+
+const headerVersion = 1
+
+// saltSize is the fixed size of the salt slot in the header. It is large
+// enough for both Argon2id and scrypt and is always written in full, even
+// when KDFNone leaves it zeroed, so the header has a constant size.
+const saltSize = 16
+
+// headerSize is the fixed on-disk size of the stream header: magic(8) +
+// version(1) + kdf(1) + suite(1) + time(4) + memory(4) + parallelism(1) +
+// chunkSize(4) + rsDataShards(1) + rsParityShards(1) + salt(16).
+const headerSize = len(magic) + 1 + 1 + 1 + 4 + 4 + 1 + 4 + 1 + 1 + saltSize
+
+// ErrInvalidHeader is returned by the first Read of a stream passed to Open
+// (or the first ReadAt of OpenAt's reader) when the stream does not start
+// with a recognized streamcrypt header, or the header's version is
+// unsupported.
+var ErrInvalidHeader = errors.New("streamcrypt: invalid or unsupported stream header")
+
+// header is the fixed-size preamble written once at the start of every
+// sealed stream, ahead of any chunks.
+type header struct {
+	version        uint8
+	kdf            KDF
+	suite          Suite
+	params         KDFParams
+	chunkSize      uint32 // 0 means chunks are length-prefixed rather than fixed-size
+	rsDataShards   uint8  // 0 means chunks aren't Reed-Solomon encoded
+	rsParityShards uint8
+	salt           []byte
+}
+
+func (h header) encode() []byte {
+	buf := make([]byte, headerSize)
+
+	n := copy(buf, magic[:])
+	buf[n] = h.version
+	n++
+	buf[n] = byte(h.kdf)
+	n++
+	buf[n] = byte(h.suite)
+	n++
+
+	binary.BigEndian.PutUint32(buf[n:], h.params.Time)
+	n += 4
+	binary.BigEndian.PutUint32(buf[n:], h.params.Memory)
+	n += 4
+
+	buf[n] = h.params.Parallelism
+	n++
+
+	binary.BigEndian.PutUint32(buf[n:], h.chunkSize)
+	n += 4
+
+	buf[n] = h.rsDataShards
+	n++
+	buf[n] = h.rsParityShards
+	n++
+
+	copy(buf[n:], h.salt)
+
+	return buf
+}
+
+func decodeHeader(r io.Reader) (header, error) {
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return header{}, err // including io.EOF
+	}
+
+	if !bytes.Equal(buf[:len(magic)], magic[:]) {
+		return header{}, ErrInvalidHeader
+	}
+	n := len(magic)
+
+	var h header
+	h.version = buf[n]
+	n++
+	if h.version != headerVersion {
+		return header{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, h.version)
+	}
+
+	h.kdf = KDF(buf[n])
+	n++
+	h.params.KDF = h.kdf
+
+	h.suite = Suite(buf[n])
+	n++
+
+	h.params.Time = binary.BigEndian.Uint32(buf[n:])
+	n += 4
+	h.params.Memory = binary.BigEndian.Uint32(buf[n:])
+	n += 4
+
+	h.params.Parallelism = buf[n]
+	n++
+
+	h.chunkSize = binary.BigEndian.Uint32(buf[n:])
+	n += 4
+
+	h.rsDataShards = buf[n]
+	n++
+	h.rsParityShards = buf[n]
+	n++
+
+	h.salt = buf[n : n+saltSize]
+
+	return h, nil
+}
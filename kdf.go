@@ -0,0 +1,72 @@
+package streamcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF identifies the password-based key derivation function recorded in a
+// stream's header.
+type KDF byte
+
+const (
+	// KDFNone marks a stream whose key was supplied directly as a
+	// cipher.Block, with no on-disk derivation parameters.
+	KDFNone KDF = iota
+	// KDFArgon2id derives the key with Argon2id and is the preferred choice
+	// for new streams.
+	KDFArgon2id
+	// KDFScrypt derives the key with scrypt, kept for interoperability with
+	// callers that can't afford Argon2id's memory cost.
+	KDFScrypt
+)
+
+// keyLen is the derived key size, matching AES-256.
+const keyLen = 32
+
+// KDFParams carries the cost parameters for password-based key derivation,
+// plus the KDF they apply to. The same fields are reused by both KDFs: for
+// KDFArgon2id, Time and Memory are the iteration count and memory in KiB;
+// for KDFScrypt, Time is log2(N) and Memory is r. Parallelism is the number
+// of lanes (Argon2id) or p (scrypt).
+type KDFParams struct {
+	KDF         KDF
+	Time        uint32
+	Memory      uint32
+	Parallelism uint8
+}
+
+// DefaultKDFParams returns OWASP-recommended Argon2id parameters: a 64 MiB
+// working set, 3 iterations, 4 lanes of parallelism.
+func DefaultKDFParams() KDFParams {
+	return KDFParams{KDF: KDFArgon2id, Time: 3, Memory: 64 * 1024, Parallelism: 4}
+}
+
+// CipherBlockFromPassword derives an AES key from password and salt using
+// params.KDF and returns the resulting cipher.Block. Unlike
+// CipherBlockFromSecret, the derivation is deliberately slow and
+// memory-hard, making it suitable for user-chosen passwords rather than
+// high-entropy secrets. salt should be random and at least 16 bytes; it is
+// not secret and is meant to be stored alongside the ciphertext.
+func CipherBlockFromPassword(password, salt []byte, params KDFParams) (cipher.Block, error) {
+	var key []byte
+
+	switch params.KDF {
+	case KDFArgon2id:
+		key = argon2.IDKey(password, salt, params.Time, params.Memory, params.Parallelism, keyLen)
+	case KDFScrypt:
+		var err error
+		key, err = scrypt.Key(password, salt, 1<<params.Time, int(params.Memory), int(params.Parallelism), keyLen)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("streamcrypt: unsupported KDF %d", params.KDF)
+	}
+
+	return aes.NewCipher(key)
+}
@@ -0,0 +1,250 @@
+package streamcrypt
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+)
+
+// parallel.go implements the worker pools behind WithParallelism: Seal
+// dispatches fixed-size plaintext chunks to sealJobs and reassembles
+// ciphertext in order before writing it downstream; Open mirrors that on the
+// read side, prefetching ciphertext chunks and dispatching them to
+// openJobs. Both sides bound the number of chunks in flight to e.parallelism,
+// so memory use still scales with parallelism * chunkSize rather than with
+// the stream length.
+
+type sealJob struct {
+	index     uint64
+	plaintext []byte
+}
+
+type sealResult struct {
+	ciphertext []byte
+}
+
+// parallelWriter holds the write-side worker pool state for one Seal call.
+type parallelWriter struct {
+	jobs     chan sealJob
+	pending  []chan sealResult // ring of one-slot channels, indexed by index % len(pending)
+	inFlight int
+	next     uint64 // index to assign the next submitted job
+	commit   uint64 // index of the next result to flush downstream
+}
+
+// startParallelWriter spins up e.parallelism workers, each with its own
+// cipher.AEAD via e.newAEAD, and must only be called once writeHeader
+// has generated e.writeFileID.
+func (e *Encryptor) startParallelWriter() {
+	n := e.parallelism
+	pw := &parallelWriter{
+		jobs:    make(chan sealJob, n),
+		pending: make([]chan sealResult, n),
+	}
+	for i := range pw.pending {
+		pw.pending[i] = make(chan sealResult, 1)
+	}
+
+	for i := 0; i < n; i++ {
+		aead, err := e.newAEAD()
+		if err != nil {
+			// e.newAEAD was already exercised when e.enc was built, so this
+			// can't actually happen; keep the worker honest rather than
+			// silently sealing with a nil AEAD.
+			panic(err)
+		}
+		go func(aead cipher.AEAD) {
+			// aad is this worker's own scratch space: workers run
+			// concurrently, so it can't be a field shared on e the way the
+			// sequential path reuses e.writeAAD.
+			var aad [fileIDSize + 8]byte
+			for job := range pw.jobs {
+				buf := make([]byte, len(job.plaintext)+aead.Overhead())
+				aead.Seal(buf[:0], nil, job.plaintext, chunkAAD(aad[:], e.writeFileID, job.index))
+				pw.pending[job.index%uint64(n)] <- sealResult{ciphertext: buf}
+			}
+		}(aead)
+	}
+
+	e.pw = pw
+}
+
+// submitWriteChunk hands plaintext off to the worker pool to be sealed as
+// the next chunk, blocking to flush the oldest in-flight result first if the
+// window is already full.
+func (e *Encryptor) submitWriteChunk(plaintext []byte) error {
+	if e.pw == nil {
+		e.startParallelWriter()
+	}
+	pw := e.pw
+
+	if pw.inFlight == len(pw.pending) {
+		if err := e.commitOneWrite(); err != nil {
+			return err
+		}
+	}
+
+	// copy since the caller (wfixedBuf) reuses its backing array right after
+	plaintextCopy := append([]byte(nil), plaintext...)
+	index := pw.next
+	pw.next++
+	pw.jobs <- sealJob{index: index, plaintext: plaintextCopy}
+	pw.inFlight++
+	return nil
+}
+
+// commitOneWrite waits for the oldest outstanding chunk and writes it
+// downstream with its length prefix, preserving chunk order regardless of
+// which worker finished it. The prefix lets nextParallelChunk tell a short
+// final data chunk apart from the sentinel Finish writes right after it,
+// the same reason writeFixedChunk prefixes its chunks.
+func (e *Encryptor) commitOneWrite() error {
+	pw := e.pw
+	res := <-pw.pending[pw.commit%uint64(len(pw.pending))]
+	pw.commit++
+	pw.inFlight--
+
+	var prefix [prefixSize]byte
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(res.ciphertext)))
+	if _, err := e.downstream.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := e.downstream.Write(res.ciphertext)
+	return err
+}
+
+// drainWrites flushes every chunk still in flight and shuts down the worker
+// pool, so Finish leaves nothing buffered and the next Seal starts clean.
+func (e *Encryptor) drainWrites() error {
+	if e.pw == nil {
+		return nil
+	}
+	for e.pw.inFlight > 0 {
+		if err := e.commitOneWrite(); err != nil {
+			return err
+		}
+	}
+	close(e.pw.jobs)
+	e.pw = nil
+	return nil
+}
+
+type openJob struct {
+	index      uint64
+	ciphertext []byte
+}
+
+type openResult struct {
+	plaintext []byte
+	err       error
+}
+
+// parallelReader holds the read-side worker pool state for one Open call.
+type parallelReader struct {
+	jobs        chan openJob
+	pending     []chan openResult // ring of one-slot channels, indexed by index % len(pending)
+	inFlight    int
+	fetch       uint64 // index to assign the next chunk read from upstream
+	deliver     uint64 // index of the next result to hand back to Read
+	fetchDone   bool   // upstream hit a clean EOF, nothing left to read
+	sawSentinel bool   // the zero-length terminating chunk has been decoded
+}
+
+// startParallelReader spins up e.parallelism workers, each with its own
+// cipher.AEAD via e.newAEAD, and must only be called once readHeader
+// has parsed e.readFileID.
+func (e *Encryptor) startParallelReader() {
+	n := e.parallelism
+	pr := &parallelReader{
+		jobs:    make(chan openJob, n),
+		pending: make([]chan openResult, n),
+	}
+	for i := range pr.pending {
+		pr.pending[i] = make(chan openResult, 1)
+	}
+
+	for i := 0; i < n; i++ {
+		aead, err := e.newAEAD()
+		if err != nil {
+			// e.newAEAD was already exercised when e.enc was built, so this
+			// can't actually happen; keep the worker honest rather than
+			// silently opening with a nil AEAD.
+			panic(err)
+		}
+		go func(aead cipher.AEAD) {
+			// aad is this worker's own scratch space, for the same reason
+			// the seal-side workers above don't share e.readAAD.
+			var aad [fileIDSize + 8]byte
+			for job := range pr.jobs {
+				plaintext, err := aead.Open(job.ciphertext[:0], nil, job.ciphertext, chunkAAD(aad[:], e.readFileID, job.index))
+				pr.pending[job.index%uint64(n)] <- openResult{plaintext: plaintext, err: err}
+			}
+		}(aead)
+	}
+
+	e.pr = pr
+}
+
+// nextParallelChunk keeps the prefetch window full, reading length-prefixed
+// ciphertext chunks from e.upstream on the calling goroutine (a single
+// io.Reader can't be read from concurrently) and dispatching each to the
+// worker pool for decryption, then returns chunks to the caller strictly in
+// order. The length prefix (written by commitOneWrite) is what lets a short
+// final data chunk be told apart from the sentinel Finish writes right after
+// it. It returns io.EOF once the sentinel chunk has been decoded, or
+// ErrTruncatedStream if the upstream ran out before that happened.
+func (e *Encryptor) nextParallelChunk() ([]byte, error) {
+	if e.pr == nil {
+		e.startParallelReader()
+	}
+	pr := e.pr
+
+	for !pr.fetchDone && pr.inFlight < len(pr.pending) {
+		var length uint32
+		err := binary.Read(e.upstream, binary.BigEndian, &length)
+		switch err {
+		case nil:
+			ciphertext := make([]byte, length)
+			if _, err := io.ReadFull(e.upstream, ciphertext); err != nil {
+				return nil, err
+			}
+			e.submitReadChunk(ciphertext)
+		case io.EOF:
+			pr.fetchDone = true
+		default:
+			return nil, err
+		}
+	}
+
+	if pr.inFlight == 0 {
+		// nothing left to fetch or deliver: shut the pool down now rather
+		// than leaving its workers blocked on pr.jobs forever
+		close(pr.jobs)
+		e.pr = nil
+		if !pr.sawSentinel {
+			return nil, ErrTruncatedStream
+		}
+		return nil, io.EOF
+	}
+
+	res := <-pr.pending[pr.deliver%uint64(len(pr.pending))]
+	pr.deliver++
+	pr.inFlight--
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	if len(res.plaintext) == 0 {
+		pr.sawSentinel = true
+		return nil, io.EOF
+	}
+	return res.plaintext, nil
+}
+
+func (e *Encryptor) submitReadChunk(ciphertext []byte) {
+	pr := e.pr
+	index := pr.fetch
+	pr.fetch++
+	pr.jobs <- openJob{index: index, ciphertext: ciphertext}
+	pr.inFlight++
+}
@@ -0,0 +1,143 @@
+package streamcrypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// OpenAt returns an io.ReaderAt over a stream that was sealed with a fixed
+// chunk size (via WithChunkSize), given the plaintext's total size. Because
+// the on-disk chunk size is fixed, ReadAt can compute which chunk(s) cover
+// the requested range, fetch exactly those ciphertext blocks from r, and
+// authenticate and decrypt only them -- the rest of the stream is never
+// touched. plaintextSize is needed to size the last, possibly shorter,
+// chunk, since OpenAt never reads that chunk's own length prefix; it
+// computes ciphertext offsets analytically instead.
+//
+// A stream also sealed with WithReedSolomon isn't supported: ReadAt's
+// offset math assumes the plain length-prefixed fixed-chunk layout, not the
+// share-header-prefixed stripes WithReedSolomon writes instead. ReadAt
+// reports this explicitly rather than silently reading the wrong bytes.
+//
+// Header parsing and key derivation happen lazily on the first ReadAt call,
+// so OpenAt itself cannot fail; errors surface from ReadAt instead. The
+// returned io.ReaderAt is safe for concurrent ReadAt calls, as the interface
+// requires.
+func (e *Encryptor) OpenAt(r io.ReaderAt, plaintextSize int64) io.ReaderAt {
+	return &randomAccessReader{enc: e, r: r, plaintextSize: plaintextSize}
+}
+
+// randomAccessReader is the io.ReaderAt returned by OpenAt.
+type randomAccessReader struct {
+	enc           *Encryptor
+	r             io.ReaderAt
+	plaintextSize int64
+
+	once       sync.Once
+	initErr    error
+	aead       cipher.AEAD
+	fileID     []byte
+	chunkSize  int64
+	dataOffset int64
+}
+
+// init lazily parses the header and derives the AEAD on the first ReadAt
+// call. It's guarded by sync.Once rather than the bool flag a single-goroutine
+// caller would use, since io.ReaderAt's contract explicitly permits concurrent
+// ReadAt calls on the same reader -- exactly the HTTP-range-request use case
+// OpenAt exists for -- and a freshly-returned reader's first two ReadAt calls
+// would otherwise race on every field it sets.
+func (ra *randomAccessReader) init() error {
+	ra.once.Do(func() {
+		buf := make([]byte, headerSize)
+		if _, err := ra.r.ReadAt(buf, 0); err != nil {
+			ra.initErr = err
+			return
+		}
+
+		h, err := decodeHeader(bytes.NewReader(buf))
+		if err != nil {
+			ra.initErr = err
+			return
+		}
+		if h.chunkSize == 0 {
+			ra.initErr = fmt.Errorf("streamcrypt: stream was not sealed with WithChunkSize, cannot be opened at random offsets")
+			return
+		}
+		if h.rsDataShards > 0 {
+			ra.initErr = fmt.Errorf("streamcrypt: stream was sealed with WithReedSolomon, OpenAt doesn't support its stripe framing")
+			return
+		}
+
+		_, aead, err := ra.enc.deriveAEAD(h)
+		if err != nil {
+			ra.initErr = err
+			return
+		}
+
+		fileID := make([]byte, fileIDSize)
+		if _, err = ra.r.ReadAt(fileID, int64(headerSize)); err != nil {
+			ra.initErr = err
+			return
+		}
+
+		ra.aead = aead
+		ra.fileID = fileID
+		ra.chunkSize = int64(h.chunkSize)
+		ra.dataOffset = int64(headerSize + fileIDSize)
+	})
+	return ra.initErr
+}
+
+// ReadAt fills p with the plaintext starting at off, fetching and
+// authenticating only the ciphertext chunk(s) that range touches.
+func (ra *randomAccessReader) ReadAt(p []byte, off int64) (int, error) {
+	if err := ra.init(); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("streamcrypt: negative ReadAt offset %d", off)
+	}
+	if off >= ra.plaintextSize {
+		return 0, io.EOF
+	}
+
+	// aad is local to this call, not a field on ra: ReadAt must support
+	// concurrent callers, so it can't reuse scratch space shared with them.
+	var aad [fileIDSize + 8]byte
+
+	var total int
+	for total < len(p) {
+		pos := off + int64(total)
+		if pos >= ra.plaintextSize {
+			return total, io.EOF
+		}
+
+		chunkIndex := pos / ra.chunkSize
+		chunkStart := chunkIndex * ra.chunkSize
+		plainLen := ra.chunkSize
+		if chunkStart+plainLen > ra.plaintextSize {
+			plainLen = ra.plaintextSize - chunkStart
+		}
+
+		ciphertext := make([]byte, plainLen+int64(ra.aead.Overhead()))
+		stride := int64(prefixSize) + ra.chunkSize + int64(ra.aead.Overhead())
+		chunkOffset := ra.dataOffset + chunkIndex*stride + int64(prefixSize)
+		if _, err := ra.r.ReadAt(ciphertext, chunkOffset); err != nil {
+			return total, err
+		}
+
+		plaintext, err := ra.aead.Open(ciphertext[:0], nil, ciphertext, chunkAAD(aad[:], ra.fileID, uint64(chunkIndex)))
+		if err != nil {
+			return total, err
+		}
+
+		skip := pos - chunkStart
+		total += copy(p[total:], plaintext[skip:])
+	}
+
+	return total, nil
+}
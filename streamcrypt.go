@@ -3,30 +3,124 @@ package streamcrypt
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"slices"
+
+	"github.com/klauspost/reedsolomon"
 )
 
 const prefixSize = 4 // 4 bytes for uint32 prefix
 
+// fileIDSize is the size of the random per-stream identifier written
+// immediately after the header and mixed into every chunk's AAD.
+const fileIDSize = 16
+
+// defaultChunkSize is the plaintext block size used by WithChunkSize when
+// called with size <= 0.
+const defaultChunkSize = 64 << 10 // 64 KiB
+
 // Buffers up to 1MB are preserved in memory, avoiding GC overhead for common cases
 const defaultMaxBufferSize = 1 << 20
 
+// ErrTruncatedStream is returned by Read when the upstream ends before the
+// authenticated zero-length chunk written by Finish, meaning the stream was
+// cut short (accidentally or by an attacker) rather than ended cleanly.
+var ErrTruncatedStream = errors.New("streamcrypt: stream ended before the final authenticated chunk")
+
+// Encryptor seals and opens a stream of AEAD-encrypted chunks, each framed
+// with a 4-byte ciphertext length prefix by default. AES-GCM is the suite
+// used unless NewEncryptorWithSuite picks a different one (see suite.go).
+// Every stream opens with a self-describing header (see header.go) followed
+// by a random per-stream FileID. Chunks are authenticated against more than
+// just tampering: each chunk's index and the stream's FileID are bound into
+// the AEAD's additional data, so an attacker who can reorder, duplicate,
+// drop, or splice chunks across streams sharing a key cannot produce a
+// chunk that still authenticates. The stream must end with an authenticated
+// zero-length chunk, written by Finish, so Read can tell a clean
+// end-of-stream from ciphertext that was truncated.
 type Encryptor struct {
 	enc cipher.AEAD
 
+	// newAEAD builds a fresh, independent cipher.AEAD for the same key and
+	// suite as enc. WithParallelism calls it once per worker so they don't
+	// share internal AEAD state, even though GCM itself would tolerate that.
+	newAEAD aeadFactory
+
+	// suite selects which AEAD primitive newAEAD/enc use; it's recorded in
+	// the header so Open knows which one to rebuild. Zero value is
+	// SuiteAESGCM, matching every constructor other than NewEncryptorWithSuite.
+	suite Suite
+
+	// key is the raw symmetric key, retained only for Encryptors built by
+	// NewEncryptorWithSuite, so Open can rebuild the suite's AEAD from
+	// whatever suite the stream's header actually records -- mirroring how
+	// password is retained below for password-based streams.
+	key []byte
+
+	// key derivation: set when the stream is password-based rather than
+	// built from a precomputed cipher.Block. kdf is KDFNone otherwise.
+	password  []byte
+	kdf       KDF
+	kdfParams KDFParams
+	salt      []byte
+
+	// chunkSize is 0 for the original variable framing, or the fixed
+	// plaintext block size set via WithChunkSize, in which case every chunk
+	// but possibly the last is exactly chunkSize plaintext bytes, so
+	// ciphertext offsets are deterministic and OpenAt can seek to any chunk
+	// directly without relying on its own length prefix. Seal writes
+	// whatever WithChunkSize configured; Open reads it back from the
+	// stream's header.
+	chunkSize int
+
+	// parallelism is the number of worker goroutines WithParallelism sets up
+	// to seal/open fixed-size chunks concurrently. 0 or 1 keeps the default
+	// sequential path used by everything else in this file.
+	parallelism int
+	pw          *parallelWriter
+	pr          *parallelReader
+
+	// sealing records which of Seal or Open configured this Encryptor most
+	// recently, so Close knows whether to finalize a sealed stream or just
+	// release a reader's pooled resources.
+	sealing bool
+
+	// rsData and rsParity are the Reed-Solomon shard counts set by
+	// WithReedSolomon, or 0 to leave chunks unprotected (see fec.go).
+	// readHeader overwrites both from the stream's header, same as
+	// chunkSize, so Open doesn't need WithReedSolomon called on it.
+	rsData        int
+	rsParity      int
+	rsShareSize   int // per-shard size once padded, fixed for the whole stream
+	rsEncoder     reedsolomon.Encoder
+	rsStats       FECStats
+	rsSawSentinel bool
+
 	// read
-	upstream io.Reader
-	rbuf     []byte
-	index    int
+	upstream       io.Reader
+	rbuf           []byte
+	index          int
+	headerRead     bool
+	readFileID     []byte
+	readChunkIndex uint64
+	readAAD        [fileIDSize + 8]byte // reused scratch space for chunkAAD
+	finished       bool
 
 	// write
-	downstream io.Writer
-	wbuf       []byte
-	wbufmax    int
-	immutable  bool
+	downstream      io.Writer
+	wbuf            []byte
+	wbufmax         int
+	immutable       bool
+	headerWritten   bool
+	writeFileID     []byte
+	writeChunkIndex uint64
+	writeAAD        [fileIDSize + 8]byte // reused scratch space for chunkAAD
+	wfixedBuf       []byte               // accumulates plaintext until it reaches chunkSize
 }
 
 func CipherBlockFromSecret(secret []byte) (cipher.Block, error) {
@@ -42,10 +136,50 @@ func NewEncryptor(cipherBlock cipher.Block) (*Encryptor, error) {
 
 	return &Encryptor{
 		enc:     enc,
+		newAEAD: func() (cipher.AEAD, error) { return cipher.NewGCMWithRandomNonce(cipherBlock) },
+		kdf:     KDFNone,
 		wbufmax: defaultMaxBufferSize,
 	}, nil
 }
 
+// NewEncryptorWithSuite builds an Encryptor that seals with the given AEAD
+// suite instead of the default AES-GCM, deriving it directly from a raw
+// symmetric key rather than a precomputed cipher.Block, since suites like
+// SuiteChaCha20Poly1305 don't use a block cipher at all. The suite is
+// recorded in the stream's header, so a separate Encryptor opening the
+// stream (also built with NewEncryptorWithSuite and the same key) doesn't
+// need to be told which suite to expect.
+func NewEncryptorWithSuite(key []byte, suite Suite) (*Encryptor, error) {
+	enc, err := buildSuiteAEAD(suite, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encryptor{
+		enc:     enc,
+		newAEAD: func() (cipher.AEAD, error) { return buildSuiteAEAD(suite, key) },
+		suite:   suite,
+		key:     key,
+		kdf:     KDFNone,
+		wbufmax: defaultMaxBufferSize,
+	}, nil
+}
+
+// NewEncryptorWithPassword builds an Encryptor that derives its key from a
+// password rather than a precomputed cipher.Block. The derivation happens
+// lazily: Seal generates a fresh random salt and derives the key on first
+// Write, while Open re-derives the key from the salt recorded in the
+// stream's header on first Read, so the same password can be reused across
+// streams without the caller ever handling a salt directly.
+func NewEncryptorWithPassword(password []byte, params KDFParams) (*Encryptor, error) {
+	return &Encryptor{
+		password:  password,
+		kdf:       params.KDF,
+		kdfParams: params,
+		wbufmax:   defaultMaxBufferSize,
+	}, nil
+}
+
 // WithImmutableSealing guarantees that data passed to Sealer won't be modified
 func (e *Encryptor) WithImmutableSealing() *Encryptor {
 	e.immutable = true
@@ -58,14 +192,188 @@ func (e *Encryptor) WithSealingBufferSize(size int) *Encryptor {
 	return e
 }
 
-// Seal encrypts given data and writes to downstream
-func (e *Encryptor) Seal(w io.Writer) io.Writer {
+// WithChunkSize switches Seal to fixed-size chunking: plaintext is split
+// into size-byte blocks (or defaultChunkSize if size <= 0) instead of one
+// chunk per Write call, so every chunk but possibly the last is exactly
+// size bytes of plaintext and the ciphertext layout is fully deterministic
+// even though each chunk still carries its own length prefix. This is what
+// lets OpenAt compute a chunk's on-disk offset directly instead of
+// streaming forward. Open reads the chunk size back from the stream's
+// header, so only the sealing side needs to call it.
+func (e *Encryptor) WithChunkSize(size int) *Encryptor {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	e.chunkSize = size
+	return e
+}
+
+// WithParallelism seals or opens fixed-size chunks across n worker
+// goroutines instead of one chunk at a time on the calling goroutine, since
+// GCM chunks are independent and trivially parallelizable. It implies fixed
+// chunking: if WithChunkSize hasn't been called, chunkSize defaults to
+// defaultChunkSize. n <= 1 restores the sequential path, which remains the
+// default so callers who never call this keep the original constant-memory
+// behavior. The in-flight window is bounded to n chunks, so memory use
+// still scales with n * chunkSize rather than with the stream length.
+func (e *Encryptor) WithParallelism(n int) *Encryptor {
+	e.parallelism = n
+	if n > 1 && e.chunkSize == 0 {
+		e.chunkSize = defaultChunkSize
+	}
+	return e
+}
+
+// Seal encrypts given data and writes to downstream. The returned
+// io.WriteCloser's Close writes the terminating zero-length chunk and
+// releases any worker pool started by WithParallelism; callers must call it
+// after their last Write (or ReadFrom), the same way they previously had to
+// call Finish.
+func (e *Encryptor) Seal(w io.Writer) io.WriteCloser {
 	e.downstream = w
 	e.wbuf = make([]byte, 0, 16)
+	e.headerWritten = false
+	e.writeChunkIndex = 0
+	e.pw = nil
+	e.sealing = true
+	if e.chunkSize > 0 {
+		e.wfixedBuf = make([]byte, 0, e.chunkSize)
+	}
 	return e
 }
 
+// writeHeader derives a fresh key (for password-based encryptors) and
+// writes the stream header and a fresh random FileID before the first
+// chunk, so the stream is self-describing and re-openable without
+// out-of-band metadata, and every chunk can be bound to this one stream.
+func (e *Encryptor) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+
+	if e.kdf != KDFNone {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+
+		block, err := CipherBlockFromPassword(e.password, salt, e.kdfParams)
+		if err != nil {
+			return err
+		}
+
+		enc, err := cipher.NewGCMWithRandomNonce(block)
+		if err != nil {
+			return err
+		}
+
+		// password-based streams always use AES-GCM: the derived key is
+		// already consumed building block, and CipherBlockFromPassword
+		// doesn't expose the raw key a non-AES suite would need
+		e.enc, e.salt = enc, salt
+		e.newAEAD = func() (cipher.AEAD, error) { return cipher.NewGCMWithRandomNonce(block) }
+	}
+
+	if e.rsData > 0 && e.rsEncoder == nil {
+		enc, err := reedsolomon.New(e.rsData, e.rsParity)
+		if err != nil {
+			return err
+		}
+		e.rsEncoder = enc
+		e.rsShareSize = rsShareSizeFor(e.chunkSize, e.enc.Overhead(), e.rsData)
+	}
+
+	h := header{
+		version:        headerVersion,
+		kdf:            e.kdf,
+		suite:          e.suite,
+		params:         e.kdfParams,
+		chunkSize:      uint32(e.chunkSize),
+		rsDataShards:   uint8(e.rsData),
+		rsParityShards: uint8(e.rsParity),
+		salt:           e.salt,
+	}
+	if _, err := e.downstream.Write(h.encode()); err != nil {
+		return err
+	}
+
+	e.writeFileID = make([]byte, fileIDSize)
+	if _, err := rand.Read(e.writeFileID); err != nil {
+		return err
+	}
+	if _, err := e.downstream.Write(e.writeFileID); err != nil {
+		return err
+	}
+
+	e.headerWritten = true
+	return nil
+}
+
+// chunkAAD writes the AEAD additional data for chunk index into dst (which
+// must have at least len(fileID)+8 bytes of capacity) and returns the used
+// slice, binding it to fileID so a chunk from one stream can never
+// authenticate as a chunk of another, and to its index so chunks can't be
+// reordered, duplicated, or dropped without detection. Callers pass in a
+// buffer they own so it can be reused across chunks instead of allocating a
+// fresh one every call -- Seal/Open take additionalData through the
+// cipher.AEAD interface, so an allocating chunkAAD defeats their own
+// constant-memory sealing path.
+func chunkAAD(dst, fileID []byte, index uint64) []byte {
+	dst = dst[:len(fileID)+8]
+	n := copy(dst, fileID)
+	binary.BigEndian.PutUint64(dst[n:], index)
+	return dst
+}
+
 func (e *Encryptor) Write(p []byte) (n int, err error) {
+	if err = e.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	if e.chunkSize <= 0 {
+		return e.writeVariableChunk(p)
+	}
+
+	// accumulate into wfixedBuf, flushing a fixed-size ciphertext chunk
+	// every time it fills up, so ciphertext chunk boundaries don't depend
+	// on the caller's Write sizes
+	total := len(p)
+	for len(p) > 0 {
+		space := e.chunkSize - len(e.wfixedBuf)
+		take := min(space, len(p))
+		e.wfixedBuf = append(e.wfixedBuf, p[:take]...)
+		p = p[take:]
+
+		if len(e.wfixedBuf) == e.chunkSize {
+			if err = e.writeChunk(e.wfixedBuf); err != nil {
+				return 0, err
+			}
+			e.wfixedBuf = e.wfixedBuf[:0]
+		}
+	}
+
+	return total, nil
+}
+
+// writeChunk seals one fixed-size chunk, dispatching to whichever framing
+// mode is configured -- Reed-Solomon, parallel, or sequential -- mirroring
+// the priority order readHeader/nextChunk use on the read side. Callers
+// outside Write (e.g. ReadFrom) use it directly to avoid the accumulation
+// copy into wfixedBuf when they already have exactly chunkSize bytes.
+func (e *Encryptor) writeChunk(p []byte) error {
+	switch {
+	case e.rsData > 0:
+		return e.writeFixedChunkFEC(p)
+	case e.parallelism > 1:
+		return e.submitWriteChunk(p)
+	default:
+		return e.writeFixedChunk(p)
+	}
+}
+
+// writeVariableChunk seals p as a single length-prefixed chunk; it's the
+// original framing used when WithChunkSize hasn't been called.
+func (e *Encryptor) writeVariableChunk(p []byte) (n int, err error) {
 	// plaintext, ciphertext and full length
 	var plen, clen, tlen = len(p), len(p) + e.enc.Overhead(), len(p) + e.Overhead()
 	var buf []byte
@@ -87,7 +395,8 @@ func (e *Encryptor) Write(p []byte) (n int, err error) {
 	}
 
 	// seal using prepared buffer, leaving some space for prefix
-	e.enc.Seal(buf[prefixSize:][:0], nil, p, nil)
+	e.enc.Seal(buf[prefixSize:][:0], nil, p, chunkAAD(e.writeAAD[:], e.writeFileID, e.writeChunkIndex))
+	e.writeChunkIndex++
 
 	// put ciphertext length prefix
 	binary.BigEndian.PutUint32(buf[:prefixSize], uint32(clen))
@@ -101,14 +410,176 @@ func (e *Encryptor) Write(p []byte) (n int, err error) {
 	return plen, nil
 }
 
-// Open decrypts data from given reader; it may retain some trailing data in internal buffer between reads
-func (e *Encryptor) Open(r io.Reader) io.Reader {
+// writeFixedChunk seals p for the fixed-chunk framing enabled by
+// WithChunkSize: every chunk but possibly the last is exactly chunkSize
+// bytes of plaintext, so OpenAt can still compute its on-disk offset
+// directly, but each chunk is still prefixed with its own ciphertext length
+// like the original variable framing. Without it, a short final data chunk
+// (plaintext not a multiple of chunkSize) couldn't be told apart from the
+// zero-length sentinel chunk Finish writes right after it.
+func (e *Encryptor) writeFixedChunk(p []byte) error {
+	buf := make([]byte, prefixSize+len(p)+e.enc.Overhead())
+	sealed := e.enc.Seal(buf[prefixSize:][:0], nil, p, chunkAAD(e.writeAAD[:], e.writeFileID, e.writeChunkIndex))
+	binary.BigEndian.PutUint32(buf[:prefixSize], uint32(len(sealed)))
+	e.writeChunkIndex++
+
+	_, err := e.downstream.Write(buf)
+	return err
+}
+
+// Finish flushes any buffered partial chunk and writes a terminating
+// zero-length authenticated chunk, marking a clean end of stream. Callers
+// must call it after their last Write so that Open can tell a stream that
+// ended cleanly from one that was truncated; omitting it makes Read on the
+// other end fail with ErrTruncatedStream.
+func (e *Encryptor) Finish() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	if e.chunkSize <= 0 {
+		_, err := e.writeVariableChunk(nil)
+		return err
+	}
+
+	if len(e.wfixedBuf) > 0 {
+		if err := e.writeChunk(e.wfixedBuf); err != nil {
+			return err
+		}
+		e.wfixedBuf = e.wfixedBuf[:0]
+	}
+	if err := e.writeChunk(nil); err != nil {
+		return err
+	}
+
+	if e.parallelism > 1 && e.rsData == 0 {
+		return e.drainWrites()
+	}
+	return nil
+}
+
+// Close finalizes whichever side of the stream Seal or Open last configured
+// this Encryptor for. For a sealer it's equivalent to Finish: it flushes any
+// buffered chunk and writes the terminating zero-length chunk. For an
+// opener it releases the worker pool started by WithParallelism, if any,
+// so abandoning a stream before reading it to io.EOF doesn't leak the pool's
+// goroutines.
+func (e *Encryptor) Close() error {
+	if e.sealing {
+		return e.Finish()
+	}
+
+	if e.pr != nil {
+		close(e.pr.jobs)
+		e.pr = nil
+	}
+	return nil
+}
+
+// Open decrypts data from given reader; it may retain some trailing data in
+// internal buffer between reads. The returned io.ReadCloser's Close releases
+// any worker pool started by WithParallelism without requiring the stream to
+// be read to io.EOF first.
+func (e *Encryptor) Open(r io.Reader) io.ReadCloser {
 	e.rbuf = nil
 	e.upstream = r
+	e.headerRead = false
+	e.readChunkIndex = 0
+	e.finished = false
+	e.pr = nil
+	e.rsSawSentinel = false
+	e.rsStats = FECStats{}
+	e.sealing = false
 	return e
 }
 
+// readHeader parses and validates the stream header and FileID on first
+// Read, and re-derives the key from the password and salt when the stream
+// is password-based rather than built from a precomputed cipher.Block.
+func (e *Encryptor) readHeader() error {
+	if e.headerRead {
+		return nil
+	}
+
+	h, err := decodeHeader(e.upstream)
+	if err != nil {
+		return err
+	}
+
+	if e.newAEAD, e.enc, err = e.deriveAEAD(h); err != nil {
+		return err
+	}
+	e.chunkSize = int(h.chunkSize)
+
+	e.rsData = int(h.rsDataShards)
+	e.rsParity = int(h.rsParityShards)
+	if e.rsData > 0 {
+		if e.rsEncoder, err = reedsolomon.New(e.rsData, e.rsParity); err != nil {
+			return err
+		}
+		e.rsShareSize = rsShareSizeFor(e.chunkSize, e.enc.Overhead(), e.rsData)
+	}
+
+	e.readFileID = make([]byte, fileIDSize)
+	if _, err = io.ReadFull(e.upstream, e.readFileID); err != nil {
+		return err
+	}
+
+	e.headerRead = true
+	return nil
+}
+
+// deriveAEAD returns the aeadFactory and AEAD to use for a stream described
+// by h. It's shared by readHeader and OpenAt, which both need to go from an
+// on-disk header to a usable key, and by WithParallelism, which needs the
+// factory to build each worker its own AEAD. There are three cases:
+//
+//   - e.key is set (NewEncryptorWithSuite): rebuild from h.suite and e.key,
+//     so Open honors whatever suite the stream's header actually records.
+//   - h.kdf is KDFNone and e.key is nil (NewEncryptor): reuse the AEAD and
+//     factory already built at construction time from the caller's
+//     cipher.Block; there's no raw key to rebuild a suite from otherwise.
+//   - h.kdf isn't KDFNone (NewEncryptorWithPassword): derive the key from
+//     e.password and h's salt and KDF parameters; always AES-GCM.
+func (e *Encryptor) deriveAEAD(h header) (aeadFactory, cipher.AEAD, error) {
+	if e.key != nil {
+		enc, err := buildSuiteAEAD(h.suite, e.key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func() (cipher.AEAD, error) { return buildSuiteAEAD(h.suite, e.key) }, enc, nil
+	}
+
+	if h.kdf == KDFNone {
+		return e.newAEAD, e.enc, nil
+	}
+
+	if e.password == nil {
+		return nil, nil, fmt.Errorf("streamcrypt: stream is password-protected but no password was configured")
+	}
+
+	block, err := CipherBlockFromPassword(e.password, h.salt, h.params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enc, err := cipher.NewGCMWithRandomNonce(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return func() (cipher.AEAD, error) { return cipher.NewGCMWithRandomNonce(block) }, enc, nil
+}
+
 func (e *Encryptor) Read(p []byte) (n int, err error) {
+	if err = e.readHeader(); err != nil {
+		return 0, err
+	}
+
+	if e.finished {
+		return 0, io.EOF
+	}
+
 	// return trailing data that remains in buffer
 	if e.index < len(e.rbuf) {
 		n = copy(p, e.rbuf[e.index:])
@@ -116,32 +587,79 @@ func (e *Encryptor) Read(p []byte) (n int, err error) {
 		return n, nil
 	}
 
-	// read chunk length
+	e.rbuf, err = e.nextChunk()
+	if err != nil {
+		if err == io.EOF {
+			e.finished = true
+		}
+		return 0, err
+	}
+
+	e.index = 0
+	n = copy(p, e.rbuf)
+	e.index = n
+	return n, nil
+}
+
+// readLengthPrefixedChunk reads a uint32 ciphertext-length prefix followed by
+// exactly that many ciphertext bytes. Both the original variable framing and
+// the fixed-chunk framing from WithChunkSize rely on it: without an explicit
+// length, a short final data chunk (plaintext not a multiple of chunkSize)
+// can't be told apart from the zero-length sentinel chunk Finish writes
+// right after it, since neither chunk is chunkSize bytes of plaintext.
+func (e *Encryptor) readLengthPrefixedChunk() ([]byte, error) {
 	var length uint32
-	err = binary.Read(e.upstream, binary.BigEndian, &length)
+	err := binary.Read(e.upstream, binary.BigEndian, &length)
 	if err != nil {
-		return 0, err // including io.EOF
+		if err == io.EOF {
+			// the upstream ended before we saw the sentinel chunk written by
+			// Finish, so this isn't a clean end of stream
+			return nil, ErrTruncatedStream
+		}
+		return nil, err
 	}
 
-	// read exact chunk length data
-	e.rbuf = make([]byte, length)
-	if _, err = io.ReadFull(e.upstream, e.rbuf); err != nil {
-		return 0, err
+	ciphertext := make([]byte, length)
+	if _, err = io.ReadFull(e.upstream, ciphertext); err != nil {
+		return nil, err
 	}
+	return ciphertext, nil
+}
 
-	// decrypt data using the same data buffer
-	e.rbuf, err = e.enc.Open(e.rbuf[:0], nil, e.rbuf, nil)
+// nextChunk decrypts and returns the next chunk's plaintext, dispatching to
+// whichever framing mode is configured -- Reed-Solomon or parallel -- mirroring
+// the priority order writeChunk uses on the write side, or reading a single
+// length-prefixed chunk itself for the sequential fixed/variable cases, which
+// share the same on-disk framing. It returns io.EOF once the sentinel chunk
+// written by Finish is decoded, or ErrTruncatedStream if the upstream ends
+// first.
+func (e *Encryptor) nextChunk() ([]byte, error) {
+	if e.chunkSize > 0 && e.rsData > 0 {
+		return e.readFixedChunkFEC()
+	}
+	if e.chunkSize > 0 && e.parallelism > 1 {
+		return e.nextParallelChunk()
+	}
+
+	ciphertext, err := e.readLengthPrefixedChunk()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// reset buffer cursor
-	e.index = 0
+	// decrypt data using the same data buffer, authenticating it against
+	// this stream's FileID and expected chunk index
+	plaintext, err := e.enc.Open(ciphertext[:0], nil, ciphertext, chunkAAD(e.readAAD[:], e.readFileID, e.readChunkIndex))
+	if err != nil {
+		return nil, err
+	}
+	e.readChunkIndex++
 
-	// copy (output) up to len(p) bytes
-	n = copy(p, e.rbuf)
-	e.index = n
-	return n, nil
+	// a zero-length chunk is the sentinel written by Finish, marking a
+	// clean end of stream
+	if len(plaintext) == 0 {
+		return nil, io.EOF
+	}
+	return plaintext, nil
 }
 
 // Overhead returns sealing overhead: nonce+tag+uint32
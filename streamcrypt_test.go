@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"runtime"
@@ -34,6 +35,9 @@ func TestPlaintext(t *testing.T) {
 	if _, err = w.Write(testData); err != nil {
 		t.Fatal(err)
 	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
 
 	// Decrypt and read
 	r := enc.Open(&buf)
@@ -47,6 +51,393 @@ func TestPlaintext(t *testing.T) {
 	}
 }
 
+func TestPasswordRoundTrip(t *testing.T) {
+	var testData = []byte("hello world")
+	var password = []byte("correct horse battery staple")
+
+	sealer, err := NewEncryptorWithPassword(password, DefaultKDFParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err = sealer.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = sealer.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fresh Encryptor, as a receiver that only knows the password would use
+	opener, err := NewEncryptorWithPassword(password, DefaultKDFParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := io.ReadAll(opener.Open(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(testData, out) {
+		t.Errorf("expected %q, got %q", testData, out)
+	}
+}
+
+func TestSealCloser(t *testing.T) {
+	var testData = []byte("hello world")
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := enc.Seal(&buf)
+	if _, err = w.Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := enc.Open(&buf)
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(testData, out) {
+		t.Errorf("expected %q, got %q", testData, out)
+	}
+}
+
+func TestReadFromWriteTo(t *testing.T) {
+	testData := genData(200*1024 + 500) // many chunks plus a short final one
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024)
+
+	var buf bytes.Buffer
+	w := enc.Seal(&buf)
+	// hide bytes.Reader's own WriteTo, which io.Copy would otherwise use
+	// instead of the sealer's ReadFrom
+	if _, err = io.Copy(w, struct{ io.Reader }{bytes.NewReader(testData)}); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r := enc.Open(&buf)
+	if _, err = io.Copy(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(testData, out.Bytes()) {
+		t.Errorf("expected %d bytes, got %d bytes (mismatch)", len(testData), out.Len())
+	}
+}
+
+func TestManualFixedChunkNonMultiple(t *testing.T) {
+	for _, chunkSize := range []int{1024, 4096} {
+		testData := genData(2500) // not a multiple of either chunk size
+
+		enc, err := NewEncryptor(secretBlock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc.WithChunkSize(chunkSize)
+
+		var buf bytes.Buffer
+		if _, err = enc.Seal(&buf).Write(testData); err != nil {
+			t.Fatal(err)
+		}
+		if err = enc.Finish(); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := io.ReadAll(enc.Open(&buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(testData, out) {
+			t.Errorf("chunkSize=%d: expected %d bytes, got %d bytes (mismatch)", chunkSize, len(testData), len(out))
+		}
+	}
+}
+
+func TestManualParallelNonMultiple(t *testing.T) {
+	testData := genData(2500) // not a multiple of chunkSize
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024).WithParallelism(4)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := io.ReadAll(enc.Open(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(testData, out) {
+		t.Errorf("expected %d bytes, got %d bytes (mismatch)", len(testData), len(out))
+	}
+}
+
+func TestSuiteRoundTrip(t *testing.T) {
+	var testData = []byte("hello world")
+	var key = bytes.Repeat([]byte("k"), 32)
+
+	for _, suite := range []Suite{SuiteAESGCM, SuiteChaCha20Poly1305, SuiteAESGCMSIV} {
+		t.Run(fmt.Sprintf("suite_%d", suite), func(t *testing.T) {
+			sealer, err := NewEncryptorWithSuite(key, suite)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if _, err = sealer.Seal(&buf).Write(testData); err != nil {
+				t.Fatal(err)
+			}
+			if err = sealer.Finish(); err != nil {
+				t.Fatal(err)
+			}
+
+			// a fresh Encryptor built from the same key, mirroring
+			// TestPasswordRoundTrip's separate-receiver setup
+			opener, err := NewEncryptorWithSuite(key, suite)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := io.ReadAll(opener.Open(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(testData, out) {
+				t.Errorf("expected %q, got %q", testData, out)
+			}
+		})
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.NewReader(bytes.Repeat([]byte("x"), headerSize))
+	if _, err = io.ReadAll(enc.Open(buf)); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("expected %v, got %v", ErrInvalidHeader, err)
+	}
+}
+
+func TestTruncatedStream(t *testing.T) {
+	var testData = []byte("hello world")
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// drop the trailing sentinel chunk Finish wrote, simulating a stream
+	// that was cut short rather than ended cleanly
+	truncated := buf.Bytes()[:buf.Len()-enc.Overhead()]
+
+	if _, err = io.ReadAll(enc.Open(bytes.NewReader(truncated))); !errors.Is(err, ErrTruncatedStream) {
+		t.Errorf("expected %v, got %v", ErrTruncatedStream, err)
+	}
+}
+
+func TestChunkReorderRejected(t *testing.T) {
+	testData := genData(3 * 1024) // several equal-sized chunks, so swapping two is a clean splice
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// each fixed chunk occupies enc.Overhead()+chunkSize bytes on the wire
+	// (length prefix + ciphertext); swap the first two chunks, which are
+	// both full-size, so the framing still parses but chunk 0's AAD no
+	// longer matches the index it's read back at
+	data := buf.Bytes()
+	stride := enc.Overhead() + 1024
+	chunk0 := headerSize + fileIDSize
+	chunk1 := chunk0 + stride
+
+	swapped := append([]byte(nil), data...)
+	copy(swapped[chunk0:chunk0+stride], data[chunk1:chunk1+stride])
+	copy(swapped[chunk1:chunk1+stride], data[chunk0:chunk0+stride])
+
+	if _, err = io.ReadAll(enc.Open(bytes.NewReader(swapped))); err == nil {
+		t.Error("expected reordered chunks to fail authentication, got nil error")
+	}
+}
+
+func TestOpenAtRandomAccess(t *testing.T) {
+	testData := genData(10*1024 + 300) // spans several chunks plus a short final one
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	ra := enc.OpenAt(bytes.NewReader(buf.Bytes()), int64(len(testData)))
+
+	for _, tc := range []struct{ off, length int }{
+		{0, 10},                  // within the first chunk
+		{1024, 16},               // exactly on a chunk boundary
+		{1500, 600},              // spans two chunks
+		{len(testData) - 30, 30}, // tail of the short final chunk
+	} {
+		got := make([]byte, tc.length)
+		n, err := ra.ReadAt(got, int64(tc.off))
+		if err != nil && err != io.EOF {
+			t.Fatalf("ReadAt(off=%d, len=%d): %v", tc.off, tc.length, err)
+		}
+		if want := testData[tc.off : tc.off+n]; !bytes.Equal(got[:n], want) {
+			t.Errorf("ReadAt(off=%d, len=%d) = %q, want %q", tc.off, tc.length, got[:n], want)
+		}
+	}
+}
+
+func TestParallelRoundTrip(t *testing.T) {
+	testData := genData(200*1024 + 777) // many chunks plus a short final one
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024).WithParallelism(4)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := io.ReadAll(enc.Open(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(testData, out) {
+		t.Errorf("expected %d bytes, got %d bytes (mismatch)", len(testData), len(out))
+	}
+}
+
+func TestReedSolomonRoundTrip(t *testing.T) {
+	testData := genData(10 * 1024) // ten full chunks
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024).WithReedSolomon(4, 2)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// flip one byte inside the second stripe's first share: corruption
+	// within a single share should be transparently repaired by the two
+	// parity shares, without surfacing an error to Read
+	stripeSize := (shareHeaderSize + enc.rsShareSize) * (enc.rsData + enc.rsParity)
+	corrupted := buf.Bytes()
+	corrupted[headerSize+fileIDSize+stripeSize+shareHeaderSize] ^= 0xff
+
+	out, err := io.ReadAll(enc.Open(bytes.NewReader(corrupted)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(testData, out) {
+		t.Errorf("expected %d bytes, got %d bytes (mismatch)", len(testData), len(out))
+	}
+
+	if stats := enc.Stats(); stats.Recovered == 0 || stats.Unrecoverable != 0 {
+		t.Errorf("expected at least one recovered chunk and none unrecoverable, got %+v", stats)
+	}
+}
+
+func TestOpenAtRejectsReedSolomon(t *testing.T) {
+	testData := genData(10 * 1024)
+
+	enc, err := NewEncryptor(secretBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.WithChunkSize(1024).WithReedSolomon(4, 2)
+
+	var buf bytes.Buffer
+	if _, err = enc.Seal(&buf).Write(testData); err != nil {
+		t.Fatal(err)
+	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	ra := enc.OpenAt(bytes.NewReader(buf.Bytes()), int64(len(testData)))
+	if _, err = ra.ReadAt(make([]byte, 10), 0); err == nil {
+		t.Error("expected OpenAt on a Reed-Solomon stream to fail, got nil error")
+	}
+}
+
 func TestGzipStream(t *testing.T) {
 	var testData = []byte("hello world")
 
@@ -67,6 +458,9 @@ func TestGzipStream(t *testing.T) {
 	if err = w.Close(); err != nil {
 		t.Fatal(err)
 	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
 
 	// Decrypt, gunzip and read
 	r, err := gzip.NewReader(enc.Open(&buf))
@@ -119,6 +513,9 @@ func TestJsonGzipStream(t *testing.T) {
 	if err = w.Close(); err != nil {
 		t.Fatal(err)
 	}
+	if err = enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
 
 	// Decrypt, gunzip, and decode json
 	r, err := gzip.NewReader(enc.Open(&buf))
@@ -182,6 +579,7 @@ func BenchmarkEncryption(b *testing.B) {
 
 				var buf bytes.Buffer
 				enc.Seal(&buf).Write(data)
+				enc.Finish()
 				encrypted := buf.Bytes()
 
 				r := enc.Open(bytes.NewReader(encrypted))
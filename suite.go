@@ -0,0 +1,173 @@
+package streamcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"unsafe"
+
+	siv "github.com/secure-io/siv-go"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Suite identifies the AEAD primitive recorded in a stream's header.
+type Suite byte
+
+const (
+	// SuiteAESGCM is AES-GCM with a random nonce, the only primitive this
+	// package supported before NewEncryptorWithSuite and the default for every
+	// constructor that doesn't ask for something else.
+	SuiteAESGCM Suite = iota
+	// SuiteChaCha20Poly1305 trades AES-NI for large speedups on platforms
+	// without AES hardware acceleration, such as ARM and mobile.
+	SuiteChaCha20Poly1305
+	// SuiteAESGCMSIV is nonce-misuse-resistant: unlike plain GCM, an
+	// accidentally repeated nonce leaks far less than full key recovery.
+	SuiteAESGCMSIV
+)
+
+// aeadFactory builds a fresh, independent cipher.AEAD for the same key and
+// suite. WithParallelism workers each call it once so they don't share
+// internal AEAD state, even though GCM itself would tolerate that.
+type aeadFactory func() (cipher.AEAD, error)
+
+// buildSuiteAEAD constructs the cipher.AEAD for suite from a raw key,
+// wrapping primitives that take an explicit nonce (ChaCha20-Poly1305,
+// AES-GCM-SIV) so every suite presents the same random-nonce-embedded-in-
+// ciphertext contract as cipher.NewGCMWithRandomNonce: callers always pass a
+// nil nonce to Seal/Open.
+func buildSuiteAEAD(suite Suite, key []byte) (cipher.AEAD, error) {
+	switch suite {
+	case SuiteAESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCMWithRandomNonce(block)
+	case SuiteChaCha20Poly1305:
+		aead, err := chacha20poly1305.New(key)
+		if err != nil {
+			return nil, err
+		}
+		return newRandomNonceAEAD(aead), nil
+	case SuiteAESGCMSIV:
+		aead, err := siv.NewGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		return newRandomNonceAEAD(aead), nil
+	default:
+		return nil, fmt.Errorf("streamcrypt: unsupported AEAD suite %d", suite)
+	}
+}
+
+// randomNonceAEAD adapts a cipher.AEAD that takes an explicit nonce into the
+// same random-nonce convention cipher.NewGCMWithRandomNonce uses: Seal
+// generates a fresh random nonce and prepends it to the ciphertext, Open
+// strips it back off, and both expect a nil nonce argument.
+type randomNonceAEAD struct {
+	aead cipher.AEAD
+}
+
+func newRandomNonceAEAD(aead cipher.AEAD) cipher.AEAD {
+	return &randomNonceAEAD{aead: aead}
+}
+
+func (r *randomNonceAEAD) NonceSize() int { return 0 }
+
+func (r *randomNonceAEAD) Overhead() int { return r.aead.NonceSize() + r.aead.Overhead() }
+
+// Seal appends dst's usual nonce||ciphertext||tag to dst, following
+// sliceForAppend so it reuses dst's backing array whenever the caller (every
+// caller in this package) passed a dst with enough capacity rather than
+// discarding the result, the same convention writeFixedChunk and friends rely
+// on. Prepending the nonce shifts the ciphertext forward by NonceSize bytes
+// relative to dst's start, which the wrapped AEAD's own overlap check rejects
+// even when dst aliases plaintext the "normal" way (same start, as
+// writeVariableChunk's in-place path does); when that happens, plaintext is
+// copied into position first so the wrapped AEAD only ever sees an exact,
+// same-start overlap.
+func (r *randomNonceAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 0 {
+		panic("streamcrypt: randomNonceAEAD.Seal takes no nonce, it generates its own")
+	}
+
+	nonceSize := r.aead.NonceSize()
+	ret, out := sliceForAppend(dst, nonceSize+len(plaintext)+r.aead.Overhead())
+	n, ciphertextOut := out[:nonceSize], out[nonceSize:]
+	if _, err := rand.Read(n); err != nil {
+		panic(err)
+	}
+
+	if overlaps(ciphertextOut, plaintext) {
+		copy(ciphertextOut, plaintext)
+		plaintext = ciphertextOut[:len(plaintext)]
+	}
+
+	r.aead.Seal(ciphertextOut[:0], n, plaintext, additionalData)
+	return ret
+}
+
+// Open mirrors Seal: it strips the leading nonce and decrypts in place. dst
+// aliasing the full ciphertext (nonce included) at the same start -- the
+// ciphertext[:0] convention nextChunk and friends use -- overlaps the
+// post-nonce plaintext at a shifted offset once the nonce is sliced off, so
+// it gets the same copy-into-place treatment as Seal before the wrapped
+// AEAD ever sees it.
+func (r *randomNonceAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 0 {
+		panic("streamcrypt: randomNonceAEAD.Open takes no nonce, it was embedded by Seal")
+	}
+
+	nonceSize, tagSize := r.aead.NonceSize(), r.aead.Overhead()
+	if len(ciphertext) < nonceSize+tagSize {
+		return nil, fmt.Errorf("streamcrypt: ciphertext shorter than the embedded nonce and tag")
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext)-nonceSize-tagSize)
+	var n []byte
+	if overlaps(out, ciphertext) {
+		n = make([]byte, nonceSize)
+		copy(n, ciphertext)
+		copy(out[:len(ciphertext)-nonceSize], ciphertext[nonceSize:])
+		ciphertext = out[:len(ciphertext)-nonceSize]
+	} else {
+		n, ciphertext = ciphertext[:nonceSize], ciphertext[nonceSize:]
+	}
+
+	if _, err := r.aead.Open(out[:0], n, ciphertext, additionalData); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// sliceForAppend extends in by n bytes, reusing its backing array when it
+// has enough capacity and allocating a fresh one otherwise, the same
+// append-or-grow idiom crypto/cipher's own GCM implementation uses to
+// support both in-place reuse and disjoint dst buffers.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+// overlaps reports whether a and b share any backing memory, the same check
+// crypto/cipher's internal alias package does for its own random-nonce GCM
+// wrapper; it's unexported here rather than reused since that package isn't
+// importable outside the standard library.
+func overlaps(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	a0 := uintptr(unsafe.Pointer(&a[0]))
+	b0 := uintptr(unsafe.Pointer(&b[0]))
+	a1 := a0 + uintptr(len(a))
+	b1 := b0 + uintptr(len(b))
+	return a0 < b1 && b0 < a1
+}